@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/deluan/gosonic/api/responses"
+)
+
+// BaseAPIController centralizes the success/error envelope every endpoint
+// in this package responds with.
+type BaseAPIController struct {
+	beego.Controller
+}
+
+// NewEmpty returns a Response pre-filled with a success status, ready for a
+// controller to attach its own fields to.
+func (c *BaseAPIController) NewEmpty() *responses.Response {
+	return &responses.Response{Status: "ok"}
+}
+
+func (c *BaseAPIController) SendResponse(response *responses.Response) {
+	c.Data["json"] = response
+	c.ServeJSON()
+}
+
+func (c *BaseAPIController) SendError(code int, message string) {
+	c.Data["json"] = map[string]interface{}{
+		"status": "failed",
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+	c.ServeJSON()
+}
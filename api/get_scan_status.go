@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/deluan/gosonic/api/responses"
+	"github.com/deluan/gosonic/persistence"
+	"github.com/deluan/gosonic/scanner"
+)
+
+type GetScanStatusController struct {
+	BaseAPIController
+}
+
+func (c *GetScanStatusController) Get() {
+	status, err := scanner.GetScanStatus(persistence.NewPropertyRepository())
+	if err != nil {
+		beego.Error("Error retrieving scan status:", err)
+		c.SendError(responses.ERROR_GENERIC, "Internal Error")
+		return
+	}
+
+	response := c.NewEmpty()
+	response.ScanStatus = &responses.ScanStatus{
+		Scanning: status.InProgress,
+		Count:    status.Processed,
+	}
+	c.SendResponse(response)
+}
@@ -0,0 +1,52 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/deluan/gosonic/api/responses"
+	"github.com/deluan/gosonic/consts"
+	"github.com/deluan/gosonic/engine"
+	. "github.com/deluan/gosonic/tests"
+	"github.com/deluan/gosonic/tests/mocks"
+	"github.com/deluan/gosonic/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetScanStatus(t *testing.T) {
+	Init(t, false)
+
+	propRepo := mocks.CreateMockPropertyRepo()
+	utils.DefineSingleton(new(engine.PropertyRepository), func() engine.PropertyRepository {
+		return propRepo
+	})
+
+	Convey("Subject: GetScanStatus Endpoint", t, func() {
+		Convey("Return fail on Property Table error", func() {
+			propRepo.SetError(true)
+			_, w := Get(AddParams("/rest/getScanStatus.view"), "TestGetScanStatus")
+
+			So(w.Body, ShouldReceiveError, responses.ERROR_GENERIC)
+		})
+		Convey("When there is no scan status yet", func() {
+			propRepo.SetError(false)
+			_, w := Get(AddParams("/rest/getScanStatus.view"), "TestGetScanStatus")
+
+			Convey("Status code should be 200", func() {
+				So(w.Code, ShouldEqual, 200)
+			})
+			Convey("Then it should report not scanning", func() {
+				So(UnindentJSON(w.Body.Bytes()), ShouldContainSubstring, `"scanStatus":{"scanning":false,"count":0}`)
+			})
+		})
+		Convey("When a scan is in progress", func() {
+			propRepo.Put(consts.ScanStatus, `{"inProgress":true,"processed":42,"total":100,"errors":0}`)
+
+			_, w := Get(AddParams("/rest/getScanStatus.view"), "TestGetScanStatus")
+
+			So(UnindentJSON(w.Body.Bytes()), ShouldContainSubstring, `"scanStatus":{"scanning":true,"count":42}`)
+		})
+		Reset(func() {
+			propRepo.SetError(false)
+		})
+	})
+}
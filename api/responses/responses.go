@@ -0,0 +1,20 @@
+package responses
+
+// Error codes used in the Subsonic-style "failed" envelope.
+const (
+	ERROR_GENERIC = 0
+)
+
+// ScanStatus is the wire representation of engine.ScanStatus, trimmed down
+// to what a client actually needs to show progress.
+type ScanStatus struct {
+	Scanning bool `json:"scanning"`
+	Count    int  `json:"count"`
+}
+
+// Response is the envelope every controller in api builds and hands to
+// BaseAPIController.SendResponse.
+type Response struct {
+	Status     string      `json:"status"`
+	ScanStatus *ScanStatus `json:"scanStatus,omitempty"`
+}
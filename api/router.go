@@ -0,0 +1,7 @@
+package api
+
+import "github.com/astaxie/beego"
+
+func init() {
+	beego.Router("/rest/getScanStatus.view", &GetScanStatusController{})
+}
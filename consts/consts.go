@@ -0,0 +1,11 @@
+package consts
+
+// Property repository keys shared by the scanner and the API layer.
+const (
+	// LastScan holds the Unix millis of the last time a scan finished.
+	LastScan = "LastScan"
+
+	// ScanStatus holds the JSON-encoded engine.ScanStatus of the last (or
+	// currently running) scan.
+	ScanStatus = "ScanStatus"
+)
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Album groups the MediaFiles that share the same artist/name pair, as
+// derived during import.
+type Album struct {
+	Id        string
+	Name      string
+	ArtistId  string
+	Artist    string
+	Year      int
+	UpdatedAt time.Time
+}
+
+type Albums []Album
+
+// AlbumRepository persists Albums to the configured store.
+type AlbumRepository interface {
+	Put(al *Album) error
+	CountAll() (int64, error)
+
+	// PurgeInactive removes any stored Album not present in active. Albums
+	// carry no folder id, so unlike MediaFileRepository.PurgeInactive the
+	// removal is not limited to the folders in folderIds.
+	PurgeInactive(active *Albums, folderIds ...string) error
+}
@@ -0,0 +1,40 @@
+package domain
+
+// Artist is the set of MediaFiles' artist name and id, deduplicated during
+// import.
+type Artist struct {
+	Id   string
+	Name string
+}
+
+type Artists []Artist
+
+// ArtistRepository persists Artists to the configured store.
+type ArtistRepository interface {
+	Put(ar *Artist) error
+	CountAll() (int64, error)
+
+	// PurgeInactive removes any stored Artist not present in active. Artists
+	// carry no folder id, so unlike MediaFileRepository.PurgeInactive the
+	// removal is not limited to the folders in folderIds.
+	PurgeInactive(active *Artists, folderIds ...string) error
+}
+
+// ArtistInfo is the denormalized (id, name) pair an ArtistIndex groups its
+// entries by.
+type ArtistInfo struct {
+	ArtistId string
+	Artist   string
+}
+
+// ArtistIndex is the set of artists filed under a single index key (eg: the
+// first letter of the artist name), as used to build an alphabetical browse.
+type ArtistIndex struct {
+	Id      string
+	Artists []ArtistInfo
+}
+
+// ArtistIndexRepository persists ArtistIndexes to the configured store.
+type ArtistIndexRepository interface {
+	Put(idx *ArtistIndex) error
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// MediaFile represents a single scanned audio file and the tags read from it.
+type MediaFile struct {
+	Id          string
+	Path        string
+	Title       string
+	Artist      string
+	ArtistId    string
+	Album       string
+	AlbumId     string
+	Year        int
+	TrackNumber int
+	DiscNumber  int
+	Genre       string
+	Duration    int
+	BitRate     int
+	CoverArtId  string
+	FolderId    string
+	UpdatedAt   time.Time
+}
+
+type MediaFiles []MediaFile
+
+// MediaFileRepository persists MediaFiles to the configured store.
+type MediaFileRepository interface {
+	Put(m *MediaFile) error
+	Delete(id string) error
+	CountAll() (int64, error)
+
+	// PurgeInactive removes any stored MediaFile not present in active. With
+	// folderIds given, only MediaFiles tagged with one of those folders are
+	// considered for removal, so a folder that wasn't part of this scan
+	// doesn't lose its files.
+	PurgeInactive(active *MediaFiles, folderIds ...string) error
+}
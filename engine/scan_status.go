@@ -0,0 +1,10 @@
+package engine
+
+// ScanStatus reports the progress of a running (or most recently finished)
+// library scan, so clients can poll it instead of blocking on the scan itself.
+type ScanStatus struct {
+	InProgress bool `json:"inProgress"`
+	Processed  int  `json:"processed"`
+	Total      int  `json:"total"`
+	Errors     int  `json:"errors"`
+}
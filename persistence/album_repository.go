@@ -0,0 +1,37 @@
+package persistence
+
+import (
+	"github.com/deluan/gosonic/domain"
+)
+
+type albumRepository struct {
+	ledisRepository
+}
+
+func NewAlbumRepository() domain.AlbumRepository {
+	r := &albumRepository{}
+	r.init("album", &domain.Album{})
+	return r
+}
+
+func (r *albumRepository) Put(al *domain.Album) error {
+	return r.saveOrUpdate(al.Id, al)
+}
+
+func (r *albumRepository) CountAll() (int64, error) {
+	return r.countAll()
+}
+
+// PurgeInactive removes any stored Album not present in active. Albums
+// aren't themselves tagged with a folder id, so there's no per-record folder
+// to filter on: a nil folder selector here means "delete anything not
+// active", whatever folderIds holds.
+func (r *albumRepository) PurgeInactive(active *domain.Albums, folderIds ...string) error {
+	activeIds := make(map[string]bool, len(*active))
+	for _, al := range *active {
+		activeIds[al.Id] = true
+	}
+	return r.purgeInactive(activeIds, nil, folderIds...)
+}
+
+var _ domain.AlbumRepository = (*albumRepository)(nil)
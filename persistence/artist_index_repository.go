@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"github.com/deluan/gosonic/domain"
+)
+
+type artistIndexRepository struct {
+	ledisRepository
+}
+
+func NewArtistIndexRepository() domain.ArtistIndexRepository {
+	r := &artistIndexRepository{}
+	r.init("artistindex", &domain.ArtistIndex{})
+	return r
+}
+
+func (r *artistIndexRepository) Put(idx *domain.ArtistIndex) error {
+	return r.saveOrUpdate(idx.Id, idx)
+}
+
+var _ domain.ArtistIndexRepository = (*artistIndexRepository)(nil)
@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"github.com/deluan/gosonic/domain"
+)
+
+type artistRepository struct {
+	ledisRepository
+}
+
+func NewArtistRepository() domain.ArtistRepository {
+	r := &artistRepository{}
+	r.init("artist", &domain.Artist{})
+	return r
+}
+
+func (r *artistRepository) Put(ar *domain.Artist) error {
+	return r.saveOrUpdate(ar.Id, ar)
+}
+
+func (r *artistRepository) CountAll() (int64, error) {
+	return r.countAll()
+}
+
+// PurgeInactive removes any stored Artist not present in active. As with
+// AlbumRepository, Artists aren't tagged with a folder id, so there's no
+// per-record folder to filter on here.
+func (r *artistRepository) PurgeInactive(active *domain.Artists, folderIds ...string) error {
+	activeIds := make(map[string]bool, len(*active))
+	for _, ar := range *active {
+		activeIds[ar.Id] = true
+	}
+	return r.purgeInactive(activeIds, nil, folderIds...)
+}
+
+var _ domain.ArtistRepository = (*artistRepository)(nil)
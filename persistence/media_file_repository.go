@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"github.com/deluan/gosonic/domain"
+)
+
+type mediaFileRepository struct {
+	ledisRepository
+}
+
+func NewMediaFileRepository() domain.MediaFileRepository {
+	r := &mediaFileRepository{}
+	r.init("mediafile", &domain.MediaFile{})
+	return r
+}
+
+func (r *mediaFileRepository) Put(m *domain.MediaFile) error {
+	return r.saveOrUpdate(m.Id, m)
+}
+
+func (r *mediaFileRepository) Delete(id string) error {
+	return r.deleteEntity(id)
+}
+
+func (r *mediaFileRepository) CountAll() (int64, error) {
+	return r.countAll()
+}
+
+func (r *mediaFileRepository) PurgeInactive(active *domain.MediaFiles, folderIds ...string) error {
+	activeIds := make(map[string]bool, len(*active))
+	for _, m := range *active {
+		activeIds[m.Id] = true
+	}
+	return r.purgeInactive(activeIds, func(rec interface{}) string {
+		return rec.(*domain.MediaFile).FolderId
+	}, folderIds...)
+}
+
+var _ domain.MediaFileRepository = (*mediaFileRepository)(nil)
@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/deluan/gosonic/domain"
+	"github.com/wtolson/go-taglib"
+)
+
+// audioExtensions are the file extensions the FileSystemScanner will read tags from.
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".m4a":  true,
+	".m4b":  true,
+	".wav":  true,
+}
+
+// FileSystemScanner is a Scanner implementation that walks a media folder on disk
+// and reads tags directly from the audio files, instead of relying on an iTunes
+// XML library. It is selected by setting the "scannerType" config to "filesystem".
+type FileSystemScanner struct {
+	mediaFiles map[string]*domain.MediaFile
+	albums     map[string]*domain.Album
+	artists    map[string]*domain.Artist
+}
+
+// ScanLibrary walks path and populates MediaFiles()/Albums()/Artists() with
+// what it finds. A fresh FileSystemScanner is expected per call (Importer
+// creates one per media folder), so there's no carry-over between roots.
+func (s *FileSystemScanner) ScanLibrary(lastModifiedSince time.Time, path string) (int, error) {
+	s.mediaFiles = make(map[string]*domain.MediaFile)
+	s.albums = make(map[string]*domain.Album)
+	s.artists = make(map[string]*domain.Artist)
+
+	// Every audio file found gets an entry in MediaFiles()/Albums()/Artists(),
+	// changed or not: Importer.importLibrary is what decides whether an
+	// unchanged file's record needs to be re-Put, by comparing UpdatedAt
+	// against lastModifiedSince. If we left unchanged files out of these
+	// maps instead, Importer would see them as gone and PurgeInactive would
+	// delete them on the very next scan.
+	total := 0
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			// An unreadable root (eg: an offline folder) is an error, so the
+			// Importer doesn't treat it as scanned and empty.
+			if p == path {
+				return err
+			}
+			beego.Warn("Error accessing", p, ":", err)
+			return nil
+		}
+		if info.IsDir() || !isAudioFile(p) {
+			return nil
+		}
+		if info.ModTime().After(lastModifiedSince) {
+			total++
+		}
+		if err := s.importFile(p, info); err != nil {
+			beego.Warn("Error reading tags from", p, ":", err)
+		}
+		return nil
+	})
+
+	return total, err
+}
+
+func (s *FileSystemScanner) importFile(path string, info os.FileInfo) error {
+	mf, err := readMediaFile(path, info)
+	if err != nil {
+		return err
+	}
+	s.mediaFiles[mf.Id] = mf
+
+	al, ok := s.albums[mf.AlbumId]
+	if !ok {
+		al = newAlbum(mf)
+		s.albums[mf.AlbumId] = al
+	}
+	al.Year = mf.Year
+	al.UpdatedAt = mf.UpdatedAt
+
+	if _, ok := s.artists[mf.ArtistId]; !ok {
+		s.artists[mf.ArtistId] = newArtist(mf)
+	}
+
+	return nil
+}
+
+// readMediaFile reads tags from a single audio file. It's shared by the
+// full-sweep FileSystemScanner and the incremental watcher, so both derive
+// the same ids and fields for a given path.
+func readMediaFile(path string, info os.FileInfo) (*domain.MediaFile, error) {
+	f, err := taglib.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	artistName := f.Artist()
+	albumName := f.Album()
+	title := f.Title()
+	artistId := hashId("artist", artistName)
+	albumId := hashId("album", artistName, albumName)
+
+	return &domain.MediaFile{
+		// Id is path-only, not path+tag hash: removeFile (called with just a
+		// path, after the file is already gone) has to recompute the same id
+		// to delete the right record, and by then the tags aren't readable
+		// any more. A path+tag hash would also change the id on every retag,
+		// turning a tag edit into a delete+re-add instead of an update.
+		Id:          hashId("track", path),
+		Path:        path,
+		Title:       title,
+		Artist:      artistName,
+		ArtistId:    artistId,
+		Album:       albumName,
+		AlbumId:     albumId,
+		Year:        int(f.Year()),
+		TrackNumber: int(f.Track()),
+		DiscNumber:  int(f.Disc()),
+		Genre:       f.Genre(),
+		Duration:    int(f.Length().Seconds()),
+		BitRate:     f.Bitrate(),
+		CoverArtId:  findCoverArt(filepath.Dir(path)),
+		UpdatedAt:   info.ModTime(),
+	}, nil
+}
+
+// coverArtFilenames are the folder-level cover art files we look for, in
+// order of preference, since go-taglib doesn't expose embedded picture
+// frames for us to read instead.
+var coverArtFilenames = []string{"cover.jpg", "cover.png", "folder.jpg", "folder.png"}
+
+// findCoverArt returns the path to the first recognized cover art file in
+// dir, or "" if none is present.
+func findCoverArt(dir string) string {
+	for _, name := range coverArtFilenames {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func newAlbum(mf *domain.MediaFile) *domain.Album {
+	return &domain.Album{Id: mf.AlbumId, Name: mf.Album, ArtistId: mf.ArtistId, Artist: mf.Artist, Year: mf.Year, UpdatedAt: mf.UpdatedAt}
+}
+
+func newArtist(mf *domain.MediaFile) *domain.Artist {
+	return &domain.Artist{Id: mf.ArtistId, Name: mf.Artist}
+}
+
+func (s *FileSystemScanner) MediaFiles() map[string]*domain.MediaFile {
+	return s.mediaFiles
+}
+
+func (s *FileSystemScanner) Albums() map[string]*domain.Album {
+	return s.albums
+}
+
+func (s *FileSystemScanner) Artists() map[string]*domain.Artist {
+	return s.artists
+}
+
+func isAudioFile(path string) bool {
+	return audioExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// hashId derives a stable id from a set of parts (eg: path, or artist+album names),
+// so the same file/album/artist always maps to the same id across scans.
+func hashId(parts ...string) string {
+	h := md5.Sum([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("%x", h)
+}
+
+var _ Scanner = (*FileSystemScanner)(nil)
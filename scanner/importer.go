@@ -2,8 +2,11 @@ package scanner
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/astaxie/beego"
@@ -23,48 +26,238 @@ type Scanner interface {
 
 type tempIndex map[string]domain.ArtistInfo
 
+// watcherDebounce is how long the watcher waits for a burst of filesystem
+// events (eg: a bulk copy) to settle before importing the changed files.
+const watcherDebounce = 5 * time.Second
+
+// StartImport kicks off a single full scan. Kept for backwards compatibility;
+// new code should call StartFullScan directly.
 func StartImport() {
-	go func() {
-		i := &Importer{
-			scanner:      &ItunesScanner{},
-			mediaFolder:  beego.AppConfig.String("musicFolder"),
-			mfRepo:       persistence.NewMediaFileRepository(),
-			albumRepo:    persistence.NewAlbumRepository(),
-			artistRepo:   persistence.NewArtistRepository(),
-			idxRepo:      persistence.NewArtistIndexRepository(),
-			propertyRepo: persistence.NewPropertyRepository(),
+	StartFullScan()
+}
+
+// StartFullScan runs a one-shot sweep of the whole media folder, the same
+// behaviour Importer has always had.
+func StartFullScan() {
+	go newImporter().RunFullScan()
+}
+
+// StartWatcher runs a long-lived scan that watches mediaFolder for changes
+// and imports/removes individual files as they happen, instead of doing
+// periodic full sweeps.
+func StartWatcher() {
+	go newImporter().RunWatcher()
+}
+
+func newImporter() *Importer {
+	return &Importer{
+		mediaFolders: mediaFolders(),
+		mfRepo:       persistence.NewMediaFileRepository(),
+		albumRepo:    persistence.NewAlbumRepository(),
+		artistRepo:   persistence.NewArtistRepository(),
+		idxRepo:      persistence.NewArtistIndexRepository(),
+		propertyRepo: persistence.NewPropertyRepository(),
+	}
+}
+
+// mediaFolders reads the configured library roots. "musicFolders" takes a
+// comma-separated list, eg "Music,Audiobooks", so users can split libraries
+// across roots without one going missing if another is offline. Falls back
+// to the older single-root "musicFolder" key.
+func mediaFolders() []string {
+	if multi := beego.AppConfig.String("musicFolders"); multi != "" {
+		var folders []string
+		for _, f := range strings.Split(multi, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				folders = append(folders, f)
+			}
 		}
-		i.Run()
-	}()
+		return folders
+	}
+	if single := beego.AppConfig.String("musicFolder"); single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+// folderId derives a stable id for a media folder root, used to tag the
+// MediaFiles scanned from it.
+func folderId(path string) string {
+	return hashId("folder", path)
+}
+
+// newScanner picks a Scanner implementation based on the "scannerType" config.
+// Defaults to "itunes" for backwards compatibility with existing installs.
+func newScanner() Scanner {
+	switch beego.AppConfig.String("scannerType") {
+	case "filesystem":
+		return &FileSystemScanner{}
+	default:
+		return &ItunesScanner{}
+	}
 }
 
-// TODO Implement a flag 'inProgress'.
 type Importer struct {
-	scanner      Scanner
-	mediaFolder  string
+	mediaFolders []string
 	mfRepo       domain.MediaFileRepository
 	albumRepo    domain.AlbumRepository
 	artistRepo   domain.ArtistRepository
 	idxRepo      domain.ArtistIndexRepository
 	propertyRepo engine.PropertyRepository
 	lastScan     time.Time
+
+	mediaFiles map[string]*domain.MediaFile
+	albums     map[string]*domain.Album
+	artists    map[string]*domain.Artist
 }
 
-func (i *Importer) Run() {
+func (i *Importer) RunFullScan() {
 	i.lastScan = i.lastModifiedSince()
-	if total, err := i.scanner.ScanLibrary(i.lastScan, i.mediaFolder); err != nil {
-		beego.Error("Error importing iTunes Library:", err)
+	i.mediaFiles = make(map[string]*domain.MediaFile)
+	i.albums = make(map[string]*domain.Album)
+	i.artists = make(map[string]*domain.Artist)
+
+	// Scanner.ScanLibrary doesn't know about folders, so each root gets its
+	// own scanner instance; we tag and merge its results ourselves, which
+	// keeps the Scanner interface (and the ItunesScanner backend) unchanged.
+	var scannedFolderIds []string
+	total := 0
+	for _, folder := range i.mediaFolders {
+		id := folderId(folder)
+		s := newScanner()
+		n, err := s.ScanLibrary(i.lastScan, folder)
+		if err != nil {
+			beego.Error("Error scanning", folder, ":", err)
+			continue
+		}
+		scannedFolderIds = append(scannedFolderIds, id)
+		total += n
+		i.mergeScanResults(s, id)
+	}
+	beego.Debug("Found", total, "tracks,",
+		len(i.mediaFiles), "songs,",
+		len(i.albums), "albums,",
+		len(i.artists), "artists")
+
+	if len(scannedFolderIds) == 0 {
+		beego.Error("No media folder could be scanned, skipping import")
 		return
-	} else {
-		beego.Debug("Found", total, "tracks,",
-			len(i.scanner.MediaFiles()), "songs,",
-			len(i.scanner.Albums()), "albums,",
-			len(i.scanner.Artists()), "artists")
 	}
-	if err := i.importLibrary(); err != nil {
+	if err := i.importLibrary(scannedFolderIds); err != nil {
 		beego.Error("Error persisting data:", err)
 	}
-	beego.Info("Finished importing tracks from iTunes Library")
+	beego.Info("Finished importing tracks from", len(scannedFolderIds), "media folder(s)")
+}
+
+// mergeScanResults folds a single folder's scan into the library-wide maps,
+// tagging each MediaFile with the folder it came from.
+func (i *Importer) mergeScanResults(s Scanner, folderId string) {
+	for id, mf := range s.MediaFiles() {
+		mf.FolderId = folderId
+		i.mediaFiles[id] = mf
+	}
+	for id, al := range s.Albums() {
+		i.albums[id] = al
+	}
+	for id, ar := range s.Artists() {
+		i.artists[id] = ar
+	}
+}
+
+// RunWatcher watches every configured media folder for changes and keeps the
+// database in sync one file at a time, for as long as the process is
+// running. It never calls PurgeInactive, since it only ever sees a subset of
+// the library at a time.
+func (i *Importer) RunWatcher() {
+	if len(i.mediaFolders) == 0 {
+		beego.Warn("No media folders configured, nothing to watch")
+		return
+	}
+
+	stop := make(chan struct{})
+	changes := make(chan []string)
+	var wg sync.WaitGroup
+	for _, folder := range i.mediaFolders {
+		folderChanges, err := watchMediaFolder(folder, watcherDebounce, stop)
+		if err != nil {
+			beego.Error("Error watching", folder, ":", err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for paths := range folderChanges {
+				changes <- paths
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(changes)
+	}()
+
+	beego.Info("Watching", len(i.mediaFolders), "media folder(s) for changes")
+	for paths := range changes {
+		i.publishProgress(engine.ScanStatus{InProgress: true, Total: len(paths)})
+		i.importChangedPaths(paths)
+		i.publishProgress(engine.ScanStatus{Processed: len(paths), Total: len(paths)})
+	}
+}
+
+// folderIdForPath returns the id of the configured media folder that
+// contains path, so watcher-driven upserts can be tagged the same way a full
+// scan would tag them.
+func (i *Importer) folderIdForPath(path string) string {
+	for _, folder := range i.mediaFolders {
+		rel, err := filepath.Rel(folder, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		return folderId(folder)
+	}
+	return ""
+}
+
+// importChangedPaths upserts or removes the given paths, one at a time,
+// instead of re-scanning the whole library.
+func (i *Importer) importChangedPaths(paths []string) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			i.removeFile(path)
+			continue
+		}
+		if err != nil || info.IsDir() || !isAudioFile(path) {
+			continue
+		}
+
+		mf, err := readMediaFile(path, info)
+		if err != nil {
+			beego.Warn("Error reading tags from", path, ":", err)
+			continue
+		}
+		mf.FolderId = i.folderIdForPath(path)
+		if err := i.mfRepo.Put(mf); err != nil {
+			beego.Error(err)
+			continue
+		}
+		if err := i.albumRepo.Put(newAlbum(mf)); err != nil {
+			beego.Error(err)
+		}
+		if err := i.artistRepo.Put(newArtist(mf)); err != nil {
+			beego.Error(err)
+		}
+		beego.Debug("Updated", path)
+	}
+}
+
+func (i *Importer) removeFile(path string) {
+	id := hashId("track", path)
+	if err := i.mfRepo.Delete(id); err != nil {
+		beego.Error("Error removing", path, ":", err)
+		return
+	}
+	beego.Debug("Removed", path)
 }
 
 func (i *Importer) lastModifiedSince() time.Time {
@@ -77,60 +270,133 @@ func (i *Importer) lastModifiedSince() time.Time {
 	return time.Unix(0, s*int64(time.Millisecond))
 }
 
-func (i *Importer) importLibrary() (err error) {
+func (i *Importer) importLibrary(scannedFolderIds []string) (err error) {
 	indexGroups := utils.ParseIndexGroups(beego.AppConfig.String("indexGroups"))
 	artistIndex := make(map[string]tempIndex)
-	mfs := make(domain.MediaFiles, len(i.scanner.MediaFiles()))
-	als := make(domain.Albums, len(i.scanner.Albums()))
-	ars := make(domain.Artists, len(i.scanner.Artists()))
+	var indexMu sync.Mutex
+
+	mfs := make(domain.MediaFiles, len(i.mediaFiles))
+	als := make(domain.Albums, len(i.albums))
+	ars := make(domain.Artists, len(i.artists))
+
+	progress := newScanProgress(len(mfs) + len(als) + len(ars))
+	workers := numScanWorkers()
+
+	beego.Debug("Saving updated data with", workers, "workers")
 
-	beego.Debug("Saving updated data")
 	j := 0
-	for _, mf := range i.scanner.MediaFiles() {
+	mfJobs := make(chan *domain.MediaFile, workers*2)
+	var mfWg sync.WaitGroup
+	mfWg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer mfWg.Done()
+			for mf := range mfJobs {
+				if err := i.mfRepo.Put(mf); err != nil {
+					beego.Error(err)
+					progress.incErrors()
+				}
+				if status, publish := progress.incProcessed(); publish {
+					i.publishProgress(status)
+				}
+			}
+		}()
+	}
+	for _, mf := range i.mediaFiles {
 		mfs[j] = *mf
 		j++
 		if mf.UpdatedAt.Before(i.lastScan) {
+			if status, publish := progress.incProcessed(); publish {
+				i.publishProgress(status)
+			}
 			continue
 		}
-		if err := i.mfRepo.Put(mf); err != nil {
-			beego.Error(err)
-		}
+		mfJobs <- mf
 	}
+	close(mfJobs)
+	mfWg.Wait()
 
 	j = 0
-	for _, al := range i.scanner.Albums() {
+	alJobs := make(chan *domain.Album, workers*2)
+	var alWg sync.WaitGroup
+	alWg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer alWg.Done()
+			for al := range alJobs {
+				if err := i.albumRepo.Put(al); err != nil {
+					beego.Error(err)
+					progress.incErrors()
+				}
+				if status, publish := progress.incProcessed(); publish {
+					i.publishProgress(status)
+				}
+			}
+		}()
+	}
+	for _, al := range i.albums {
 		als[j] = *al
 		j++
 		if al.UpdatedAt.Before(i.lastScan) {
+			if status, publish := progress.incProcessed(); publish {
+				i.publishProgress(status)
+			}
 			continue
 		}
-		if err := i.albumRepo.Put(al); err != nil {
-			beego.Error(err)
-		}
+		alJobs <- al
 	}
+	close(alJobs)
+	alWg.Wait()
 
 	j = 0
-	for _, ar := range i.scanner.Artists() {
+	arJobs := make(chan *domain.Artist, workers*2)
+	var arWg sync.WaitGroup
+	arWg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer arWg.Done()
+			for ar := range arJobs {
+				if err := i.artistRepo.Put(ar); err != nil {
+					beego.Error(err)
+					progress.incErrors()
+				}
+				indexMu.Lock()
+				i.collectIndex(indexGroups, ar, artistIndex)
+				indexMu.Unlock()
+				if status, publish := progress.incProcessed(); publish {
+					i.publishProgress(status)
+				}
+			}
+		}()
+	}
+	for _, ar := range i.artists {
 		ars[j] = *ar
 		j++
-		if err := i.artistRepo.Put(ar); err != nil {
-			beego.Error(err)
-		}
-		i.collectIndex(indexGroups, ar, artistIndex)
+		arJobs <- ar
 	}
+	close(arJobs)
+	arWg.Wait()
+
+	// Workers publish outside the progress lock, so an earlier snapshot can
+	// land after the last one; publish the final counts once more here.
+	i.publishProgress(progress.snapshot())
 
+	// All workers have drained by this point, so it's safe to save the
+	// index and purge, same as the old serial importLibrary did.
 	if err = i.saveIndex(artistIndex); err != nil {
 		beego.Error(err)
 	}
 
 	beego.Debug("Purging old data")
-	if err := i.mfRepo.PurgeInactive(&mfs); err != nil {
+	// Scope the purge to the folders we actually scanned this run, so a
+	// folder that's temporarily offline doesn't lose its MediaFiles.
+	if err := i.mfRepo.PurgeInactive(&mfs, scannedFolderIds...); err != nil {
 		beego.Error(err)
 	}
-	if err := i.albumRepo.PurgeInactive(&als); err != nil {
+	if err := i.albumRepo.PurgeInactive(&als, scannedFolderIds...); err != nil {
 		beego.Error(err)
 	}
-	if err := i.artistRepo.PurgeInactive(&ars); err != nil {
+	if err := i.artistRepo.PurgeInactive(&ars, scannedFolderIds...); err != nil {
 		beego.Error(err)
 	}
 
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/deluan/gosonic/consts"
+	"github.com/deluan/gosonic/domain"
+	"github.com/deluan/gosonic/engine"
+	. "github.com/deluan/gosonic/tests"
+	"github.com/deluan/gosonic/tests/mocks"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestImporter builds an Importer backed by mocks, pre-populated with n
+// MediaFiles (and their Albums/Artists), as if a scan had already run.
+func newTestImporter(n int) (*Importer, *mocks.MockMediaFileRepository, engine.PropertyRepository) {
+	mfRepo := mocks.CreateMockMediaFileRepo()
+	albumRepo := mocks.CreateMockAlbumRepo()
+	artistRepo := mocks.CreateMockArtistRepo()
+	idxRepo := mocks.CreateMockArtistIndexRepo()
+	propRepo := mocks.CreateMockPropertyRepo()
+
+	i := &Importer{
+		mfRepo:       mfRepo,
+		albumRepo:    albumRepo,
+		artistRepo:   artistRepo,
+		idxRepo:      idxRepo,
+		propertyRepo: propRepo,
+		mediaFiles:   make(map[string]*domain.MediaFile),
+		albums:       make(map[string]*domain.Album),
+		artists:      make(map[string]*domain.Artist),
+	}
+	for k := 0; k < n; k++ {
+		mf := &domain.MediaFile{
+			Id:       fmt.Sprintf("mf%d", k),
+			Title:    fmt.Sprintf("Track %d", k),
+			ArtistId: fmt.Sprintf("ar%d", k),
+			AlbumId:  fmt.Sprintf("al%d", k),
+		}
+		i.mediaFiles[mf.Id] = mf
+		i.albums[mf.AlbumId] = &domain.Album{Id: mf.AlbumId, ArtistId: mf.ArtistId}
+		i.artists[mf.ArtistId] = &domain.Artist{Id: mf.ArtistId, Name: fmt.Sprintf("Artist %d", k)}
+	}
+	return i, mfRepo, propRepo
+}
+
+func scanStatus(propRepo engine.PropertyRepository) (engine.ScanStatus, error) {
+	data, err := propRepo.Get(consts.ScanStatus)
+	if err != nil {
+		return engine.ScanStatus{}, err
+	}
+	var status engine.ScanStatus
+	err = json.Unmarshal([]byte(data), &status)
+	return status, err
+}
+
+func TestImportLibrary(t *testing.T) {
+	Init(t, false)
+
+	Convey("Subject: Importer.importLibrary", t, func() {
+		Convey("It should process every MediaFile/Album/Artist regardless of worker ordering", func() {
+			const total = 200
+			i, _, propRepo := newTestImporter(total)
+
+			err := i.importLibrary(nil)
+			So(err, ShouldBeNil)
+
+			status, err := scanStatus(propRepo)
+			So(err, ShouldBeNil)
+			So(status.Processed, ShouldEqual, status.Total)
+			So(status.InProgress, ShouldBeFalse)
+		})
+
+		Convey("It should keep processing the other items when a worker's Put fails", func() {
+			const total = 50
+			i, mfRepo, propRepo := newTestImporter(total)
+			mfRepo.SetError(true)
+
+			err := i.importLibrary(nil)
+			So(err, ShouldBeNil)
+
+			status, err := scanStatus(propRepo)
+			So(err, ShouldBeNil)
+			So(status.Processed, ShouldEqual, status.Total)
+			So(status.Errors, ShouldBeGreaterThan, 0)
+		})
+	})
+}
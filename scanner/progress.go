@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/astaxie/beego"
+	"github.com/deluan/gosonic/consts"
+	"github.com/deluan/gosonic/engine"
+)
+
+// progressPublishEvery controls how often a running worker pool writes its
+// progress to the property repo, so polling clients don't hammer the store.
+const progressPublishEvery = 100
+
+// defaultScanWorkers is used when "scanner.workers" isn't set in the config.
+const defaultScanWorkers = 4
+
+// scanProgress tracks processed/total/error counts across a pool of workers.
+type scanProgress struct {
+	mu        sync.Mutex
+	processed int
+	total     int
+	errors    int
+}
+
+func newScanProgress(total int) *scanProgress {
+	return &scanProgress{total: total}
+}
+
+// incProcessed records one more item as done and reports whether this is a
+// good moment to publish the current status (every progressPublishEvery
+// items, or the last one).
+func (p *scanProgress) incProcessed() (engine.ScanStatus, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+	publish := p.processed%progressPublishEvery == 0 || p.processed == p.total
+	return p.snapshot(), publish
+}
+
+func (p *scanProgress) incErrors() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errors++
+}
+
+func (p *scanProgress) snapshot() engine.ScanStatus {
+	return engine.ScanStatus{
+		InProgress: p.processed < p.total,
+		Processed:  p.processed,
+		Total:      p.total,
+		Errors:     p.errors,
+	}
+}
+
+// publishProgress persists status as JSON under consts.ScanStatus, so
+// GetScanStatus can report it back to a polling client.
+func (i *Importer) publishProgress(status engine.ScanStatus) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		beego.Error("Error marshalling scan status:", err)
+		return
+	}
+	if err := i.propertyRepo.Put(consts.ScanStatus, string(data)); err != nil {
+		beego.Error("Error publishing scan status:", err)
+	}
+}
+
+// GetScanStatus reads back the status of the last (or currently running)
+// scan, for API endpoints that want to report it to a polling client.
+func GetScanStatus(propertyRepo engine.PropertyRepository) (engine.ScanStatus, error) {
+	var status engine.ScanStatus
+	data, err := propertyRepo.Get(consts.ScanStatus)
+	if err != nil || data == "" {
+		return status, err
+	}
+	err = json.Unmarshal([]byte(data), &status)
+	return status, err
+}
+
+// numScanWorkers returns the configured size of the import worker pool.
+func numScanWorkers() int {
+	n := beego.AppConfig.DefaultInt("scanner.workers", defaultScanWorkers)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
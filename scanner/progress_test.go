@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScanProgress(t *testing.T) {
+	Convey("Subject: scanProgress", t, func() {
+		Convey("It should report the final snapshot regardless of worker ordering", func() {
+			const total = 50
+			progress := newScanProgress(total)
+
+			var wg sync.WaitGroup
+			wg.Add(total)
+			for n := 0; n < total; n++ {
+				go func() {
+					defer wg.Done()
+					progress.incProcessed()
+				}()
+			}
+			wg.Wait()
+
+			status := progress.snapshot()
+			So(status.Processed, ShouldEqual, total)
+			So(status.Total, ShouldEqual, total)
+			So(status.InProgress, ShouldBeFalse)
+		})
+
+		Convey("It should count errors reported from any worker", func() {
+			progress := newScanProgress(10)
+
+			var wg sync.WaitGroup
+			wg.Add(10)
+			for n := 0; n < 10; n++ {
+				go func(n int) {
+					defer wg.Done()
+					if n%2 == 0 {
+						progress.incErrors()
+					}
+					progress.incProcessed()
+				}(n)
+			}
+			wg.Wait()
+
+			So(progress.snapshot().Errors, ShouldEqual, 5)
+		})
+
+		Convey("It should only signal publish on the configured interval and on the last item", func() {
+			progress := newScanProgress(progressPublishEvery + 1)
+
+			var published int
+			for n := 0; n < progressPublishEvery+1; n++ {
+				if _, publish := progress.incProcessed(); publish {
+					published++
+				}
+			}
+
+			So(published, ShouldEqual, 2)
+		})
+	})
+}
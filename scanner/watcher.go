@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchMediaFolder watches path recursively and, after each burst of
+// filesystem activity settles for debounce, sends the set of changed paths
+// on the returned channel. Closing stop stops the watcher and closes the
+// channel.
+func watchMediaFolder(path string, debounce time.Duration, stop <-chan struct{}) (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addRecursive(watcher, path); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		defer watcher.Close()
+
+		pending := make(map[string]bool)
+
+		// debounce is read from this same goroutine's select loop (instead
+		// of firing flush on its own goroutine via time.AfterFunc), so
+		// pending never needs a mutex: only one goroutine ever touches it.
+		timer := time.NewTimer(debounce)
+		if !timer.Stop() {
+			<-timer.C
+		}
+		defer timer.Stop()
+
+		flush := func() {
+			if len(pending) == 0 {
+				return
+			}
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			pending = make(map[string]bool)
+			out <- paths
+		}
+
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&fsnotify.Create == fsnotify.Create {
+					if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+						watcher.Add(ev.Name)
+					}
+				}
+				pending[ev.Name] = true
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(debounce)
+			case <-timer.C:
+				flush()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				beego.Warn("Media folder watcher error:", err)
+			case <-stop:
+				flush()
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// addRecursive registers a watch on path and every subdirectory beneath it,
+// since fsnotify only watches the directories it's explicitly given.
+func addRecursive(watcher *fsnotify.Watcher, path string) error {
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(p)
+		}
+		return nil
+	})
+}
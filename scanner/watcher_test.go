@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWatchMediaFolder(t *testing.T) {
+	Convey("Subject: watchMediaFolder", t, func() {
+		dir, err := ioutil.TempDir("", "gosonic-watcher-test")
+		So(err, ShouldBeNil)
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+
+		stop := make(chan struct{})
+		changes, err := watchMediaFolder(dir, 50*time.Millisecond, stop)
+		So(err, ShouldBeNil)
+
+		Convey("It should coalesce a burst of writes into a single batch", func() {
+			path := filepath.Join(dir, "track.mp3")
+			for n := 0; n < 5; n++ {
+				So(ioutil.WriteFile(path, []byte("x"), 0644), ShouldBeNil)
+			}
+
+			select {
+			case paths := <-changes:
+				So(paths, ShouldContain, path)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for debounced change")
+			}
+
+			close(stop)
+		})
+
+		Convey("It should stop and close the channel when stop is closed", func() {
+			close(stop)
+
+			select {
+			case _, ok := <-changes:
+				So(ok, ShouldBeFalse)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for watcher to stop")
+			}
+		})
+	})
+}